@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/johanbrandhorst/certify/issuers/vault"
+	"github.com/johanbrandhorst/certify/issuers/vault/auth"
 )
 
 // Issuer is an enumeration of supported issuers
@@ -17,6 +18,7 @@ const (
 	VaultIssuer = iota
 	CFSSLIssuer
 	AWSIssuer
+	StepCAIssuer
 )
 
 // UnmarshalText implements encoding.TextUnmarshaler for issuer.
@@ -28,8 +30,10 @@ func (i *Issuer) UnmarshalText(in []byte) error {
 		*i = CFSSLIssuer
 	case "aws", "amazon", "acmpca", "awscmpca":
 		*i = AWSIssuer
+	case "stepca", "smallstep":
+		*i = StepCAIssuer
 	default:
-		return errors.New(`invalid issuer specified, supported issuers are "vault", "cfssl" and "aws"`)
+		return errors.New(`invalid issuer specified, supported issuers are "vault", "cfssl", "aws" and "stepca"`)
 	}
 	return nil
 }
@@ -42,6 +46,13 @@ const (
 	UnknownAuthMethod = iota
 	ConstantTokenAuthMethod
 	RenewingTokenAuthMethod
+	AppRoleAuthMethod
+	KubernetesAuthMethod
+	AWSIAMAuthMethod
+	JWTAuthMethod
+	TLSCertAuthMethod
+	UserpassAuthMethod
+	FileTokenAuthMethod
 )
 
 // UnmarshalText implements encoding.TextUnmarshaler for AuthMethod.
@@ -51,6 +62,20 @@ func (am *AuthMethod) UnmarshalText(in []byte) error {
 		*am = ConstantTokenAuthMethod
 	case "renewing", "renewing_token":
 		*am = RenewingTokenAuthMethod
+	case "approle", "app_role":
+		*am = AppRoleAuthMethod
+	case "kubernetes", "k8s":
+		*am = KubernetesAuthMethod
+	case "aws", "aws_iam", "aws-iam":
+		*am = AWSIAMAuthMethod
+	case "jwt", "oidc", "jwt_oidc":
+		*am = JWTAuthMethod
+	case "cert", "tls_cert", "tls-cert":
+		*am = TLSCertAuthMethod
+	case "userpass":
+		*am = UserpassAuthMethod
+	case "file", "file_token", "file-token":
+		*am = FileTokenAuthMethod
 	default:
 		*am = UnknownAuthMethod
 	}
@@ -61,19 +86,29 @@ func (am *AuthMethod) UnmarshalText(in []byte) error {
 type Vault struct {
 	URL                     url.URL    `desc:"The URL of the Vault instance."`
 	Token                   string     `desc:"The Vault secret token that should be used when issuing certificates. DEPRECATED; use AuthMethod instead."`
-	AuthMethod              AuthMethod `split_words:"true" desc:"The method to use for authenticating against Vault. Supported methods are constant and renewing."`
+	AuthMethod              AuthMethod `split_words:"true" desc:"The method to use for authenticating against Vault. Supported methods are constant, renewing, approle, kubernetes, aws_iam, jwt, cert, userpass and file_token."`
 	AuthMethodRenewingToken struct {
 		Initial     string        `desc:"The token used to initially authenticate against Vault. It must be renewable."`
 		RenewBefore time.Duration `split_words:"true" default:"30m" desc:"How long before the expiry of the token it should be renewed."`
 		TimeToLive  time.Duration `split_words:"true" default:"24h" desc:"How long the new token should be valid for."`
 	} `split_words:"true" desc:"Configuration of the renewing token."`
-	AuthMethodConstantToken      vault.ConstantToken `split_words:"true" desc:"The constant token to use when talking to Vault."`
-	Mount                        string              `default:"pki" desc:"The name under which the PKI secrets engine is mounted."`
-	Role                         string              `desc:"The Vault Role that should be used when issuing certificates."`
-	CACertPath                   string              `envconfig:"CA_CERT_PATH" desc:"The path to the CA cert to use when connecting to Vault. If not set, will use publically trusted CAs."`
-	TimeToLive                   time.Duration       `split_words:"true" default:"720h" desc:"Configures the lifetime of certificates requested from the Vault server."`
-	URISubjectAlternativeNames   []string            `envconfig:"URI_SUBJECT_ALTERNATIVE_NAMES" desc:"Custom URI SANs that should be used in issued certificates. The format is a URI and must match the value specified in allowed_uri_sans, eg spiffe://hostname/foobar."`
-	OtherSubjectAlternativeNames []string            `envconfig:"OTHER_SUBJECT_ALTERNATIVE_NAMES" desc:"Custom OID/UTF8-string SANs that should be used in issued certificates. The format is the same as OpenSSL: <oid>;<type>:<value> where the only current valid <type> is UTF8."`
+	AuthMethodConstantToken vault.ConstantToken `split_words:"true" desc:"The constant token to use when talking to Vault."`
+	AuthMethodAppRole       auth.AppRole        `split_words:"true" desc:"Configuration of the AppRole auth method."`
+	AuthMethodKubernetes    auth.Kubernetes     `split_words:"true" desc:"Configuration of the Kubernetes auth method."`
+	AuthMethodAWSIAM        auth.AWSIAM         `split_words:"true" desc:"Configuration of the AWS IAM auth method."`
+	AuthMethodJWT           auth.JWT            `split_words:"true" desc:"Configuration of the JWT/OIDC auth method."`
+	AuthMethodTLSCert       auth.TLSCert        `split_words:"true" desc:"Configuration of the TLS Cert auth method."`
+	AuthMethodUserpass      auth.Userpass       `split_words:"true" desc:"Configuration of the Userpass auth method."`
+	AuthMethodFileToken     struct {
+		Path   string `desc:"The path of the file to read the Vault token from. The file is watched and the token updated in place whenever its contents change."`
+		EnvVar string `split_words:"true" desc:"The name of an environment variable to read the Vault token from, instead of Path."`
+	} `split_words:"true" desc:"Configuration of the file-backed token."`
+	Mount                        string        `default:"pki" desc:"The name under which the PKI secrets engine is mounted."`
+	Role                         string        `desc:"The Vault Role that should be used when issuing certificates."`
+	CACertPath                   string        `envconfig:"CA_CERT_PATH" desc:"The path to the CA cert to use when connecting to Vault. If not set, will use publically trusted CAs."`
+	TimeToLive                   time.Duration `split_words:"true" default:"720h" desc:"Configures the lifetime of certificates requested from the Vault server."`
+	URISubjectAlternativeNames   []string      `envconfig:"URI_SUBJECT_ALTERNATIVE_NAMES" desc:"Custom URI SANs that should be used in issued certificates. The format is a URI and must match the value specified in allowed_uri_sans, eg spiffe://hostname/foobar."`
+	OtherSubjectAlternativeNames []string      `envconfig:"OTHER_SUBJECT_ALTERNATIVE_NAMES" desc:"Custom OID/UTF8-string SANs that should be used in issued certificates. The format is the same as OpenSSL: <oid>;<type>:<value> where the only current valid <type> is UTF8."`
 }
 
 // CFSSL issuer configuration.
@@ -92,3 +127,65 @@ type AWS struct {
 	CertificateAuthorityARN string `envconfig:"CERTIFICATE_AUTHORITY_ARN" desc:"The ARN of a pre-created CA which will be used to issue the certificates."`
 	TimeToLive              int    `default:"30" desc:"The lifetime of certificates requested from the AWS CA, in number of days."`
 }
+
+// ProvisionerKind is an enumeration of supported step-ca provisioner kinds.
+type ProvisionerKind int
+
+// Supported provisioner kinds
+const (
+	UnknownProvisionerKind = iota
+	JWKProvisionerKind
+	OIDCProvisionerKind
+	AWSIIDProvisionerKind
+	GCPIIDProvisionerKind
+	AzureIIDProvisionerKind
+)
+
+// UnmarshalText implements encoding.TextUnmarshaler for ProvisionerKind.
+func (p *ProvisionerKind) UnmarshalText(in []byte) error {
+	switch strings.ToLower(string(in)) {
+	case "jwk":
+		*p = JWKProvisionerKind
+	case "oidc":
+		*p = OIDCProvisionerKind
+	case "aws", "aws_iid":
+		*p = AWSIIDProvisionerKind
+	case "gcp", "gcp_iid":
+		*p = GCPIIDProvisionerKind
+	case "azure", "azure_iid":
+		*p = AzureIIDProvisionerKind
+	default:
+		*p = UnknownProvisionerKind
+	}
+	return nil
+}
+
+// StepCA issuer configuration.
+type StepCA struct {
+	URL             string          `desc:"The base URL of the step-ca instance."`
+	RootFingerprint string          `split_words:"true" desc:"The SHA-256 fingerprint of the step-ca root certificate, used to bootstrap trust the way the step CLI does."`
+	ProvisionerKind ProvisionerKind `split_words:"true" desc:"The kind of step-ca provisioner to authenticate with. Supported kinds are jwk, oidc, aws, gcp and azure."`
+	ProvisionerJWK  struct {
+		Name     string `desc:"The name of the JWK provisioner."`
+		KeyFile  string `split_words:"true" desc:"The path to the (optionally encrypted) JWK private key."`
+		Password string `desc:"The password used to decrypt KeyFile, if it is encrypted."`
+	} `split_words:"true" desc:"Configuration of the JWK provisioner."`
+	ProvisionerOIDC struct {
+		ClientID      string `split_words:"true" desc:"The OIDC client ID."`
+		ClientSecret  string `split_words:"true" desc:"The OIDC client secret."`
+		RefreshToken  string `split_words:"true" desc:"A refresh token used to obtain new ID tokens. If unset, the device flow is used instead."`
+		DeviceAuthURL string `split_words:"true" desc:"The OIDC provider's device authorization endpoint, used when RefreshToken is unset."`
+		TokenURL      string `split_words:"true" desc:"The OIDC provider's token endpoint."`
+		Scope         string `desc:"The OIDC scope to request during the device flow."`
+	} `split_words:"true" desc:"Configuration of the OIDC provisioner."`
+	ProvisionerAWSIID struct {
+		MetadataURL string `split_words:"true" desc:"Overrides the default EC2 instance metadata service URL."`
+	} `split_words:"true" desc:"Configuration of the AWS instance-identity-document provisioner."`
+	ProvisionerGCPIID struct {
+		Audience    string `desc:"The audience to request the GCP identity token for."`
+		MetadataURL string `split_words:"true" desc:"Overrides the default GCE metadata service URL."`
+	} `split_words:"true" desc:"Configuration of the GCP instance-identity-document provisioner."`
+	ProvisionerAzureIID struct {
+		MetadataURL string `split_words:"true" desc:"Overrides the default Azure instance metadata service URL."`
+	} `split_words:"true" desc:"Configuration of the Azure instance-identity-document provisioner."`
+}
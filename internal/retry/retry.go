@@ -0,0 +1,38 @@
+// Package retry provides a small jittered-backoff helper for retrying
+// background work after a transient failure, without hammering a
+// struggling dependency in a tight loop.
+package retry
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// Waiter waits a jittered duration between Min and Max before a retry
+// is attempted.
+type Waiter struct {
+	// Min is the minimum duration to wait.
+	Min time.Duration
+	// Max is the maximum duration to wait.
+	Max time.Duration
+}
+
+// Wait blocks for a random duration in [Min, Max), returning false
+// without waiting the full duration if ctx is cancelled first.
+func (w Waiter) Wait(ctx context.Context) bool {
+	d := w.Min
+	if w.Max > w.Min {
+		d += time.Duration(rand.Int63n(int64(w.Max - w.Min)))
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
@@ -0,0 +1,126 @@
+package provisioner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// AWSIID implements Provisioner using step-ca's AWS instance-identity
+// provisioner: it fetches the signed PKCS7 instance identity document
+// from the EC2 metadata service and forwards it as-is, letting step-ca
+// verify the AWS signature itself.
+type AWSIID struct {
+	// MetadataURL overrides the default EC2 instance metadata service
+	// endpoint for the signed identity document.
+	MetadataURL string
+}
+
+const defaultAWSMetadataURL = "http://169.254.169.254/latest/dynamic/instance-identity/pkcs7"
+
+// Token fetches and returns the instance identity document.
+func (a AWSIID) Token(ctx context.Context, _ [32]byte) (string, error) {
+	u := a.MetadataURL
+	if u == "" {
+		u = defaultAWSMetadataURL
+	}
+	return fetchMetadata(ctx, u, nil)
+}
+
+// GCPIID implements Provisioner using step-ca's GCP instance-identity
+// provisioner: it fetches a signed identity token from the GCE metadata
+// service, scoped to Audience, and forwards it as-is.
+type GCPIID struct {
+	// Audience is the audience the identity token should be issued for,
+	// typically the step-ca /1.0/sign URL.
+	Audience string
+	// MetadataURL overrides the default GCE metadata service endpoint.
+	MetadataURL string
+}
+
+const defaultGCPMetadataURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/identity?format=full"
+
+// Token fetches and returns the GCP identity token.
+func (g GCPIID) Token(ctx context.Context, _ [32]byte) (string, error) {
+	raw := g.MetadataURL
+	if raw == "" {
+		raw = defaultGCPMetadataURL
+	}
+
+	if g.Audience != "" {
+		parsed, err := url.Parse(raw)
+		if err != nil {
+			return "", fmt.Errorf("stepca: failed to parse GCP metadata URL: %w", err)
+		}
+		q := parsed.Query()
+		q.Set("audience", g.Audience)
+		parsed.RawQuery = q.Encode()
+		raw = parsed.String()
+	}
+
+	return fetchMetadata(ctx, raw, map[string]string{"Metadata-Flavor": "Google"})
+}
+
+// AzureIID implements Provisioner using step-ca's Azure
+// instance-identity provisioner: it fetches the signed attestation
+// document from the Azure Instance Metadata Service and forwards its
+// signature, letting step-ca verify it against Azure's public key.
+type AzureIID struct {
+	// MetadataURL overrides the default Azure instance metadata service
+	// endpoint.
+	MetadataURL string
+}
+
+const defaultAzureMetadataURL = "http://169.254.169.254/metadata/attested/document?api-version=2020-09-01"
+
+// Token fetches the Azure attested document and returns its signature.
+func (a AzureIID) Token(ctx context.Context, _ [32]byte) (string, error) {
+	u := a.MetadataURL
+	if u == "" {
+		u = defaultAzureMetadataURL
+	}
+
+	body, err := fetchMetadata(ctx, u, map[string]string{"Metadata": "true"})
+	if err != nil {
+		return "", err
+	}
+
+	var doc struct {
+		Signature string `json:"signature"`
+	}
+	if err := json.Unmarshal([]byte(body), &doc); err != nil {
+		return "", fmt.Errorf("stepca: failed to parse Azure attested document: %w", err)
+	}
+	return doc.Signature, nil
+}
+
+// fetchMetadata GETs url with the given headers and returns the
+// response body as a string.
+func fetchMetadata(ctx context.Context, u string, headers map[string]string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return "", fmt.Errorf("stepca: failed to build metadata request: %w", err)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("stepca: failed to fetch instance identity document: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("stepca: metadata service returned %s", resp.Status)
+	}
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("stepca: failed to read instance identity document: %w", err)
+	}
+	return string(b), nil
+}
@@ -0,0 +1,106 @@
+package provisioner
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"time"
+
+	jose "github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+	"github.com/google/uuid"
+)
+
+// JWK implements Provisioner using a step-ca JWK provisioner: a JSON
+// Web Key, optionally password-encrypted the way `step ca provisioner
+// add` writes it, used to sign a short-lived JWT per request.
+type JWK struct {
+	// Name is the provisioner's name, used as the iss/sub claims.
+	Name string
+	// KeyFile is the path to the (optionally encrypted) JWK private
+	// key, as written to a provisioner.json-style file.
+	KeyFile string
+	// Password decrypts KeyFile if it is encrypted.
+	Password string
+	// Audience is the full /1.0/sign URL the token is scoped to.
+	Audience string
+}
+
+// Token signs a JWT authorizing a certificate request for the CSR whose
+// SHA-256 digest is csrSHA256.
+func (j JWK) Token(ctx context.Context, csrSHA256 [32]byte) (string, error) {
+	key, err := j.loadKey()
+	if err != nil {
+		return "", err
+	}
+
+	var alg jose.SignatureAlgorithm
+	switch key.(type) {
+	case *ecdsa.PrivateKey:
+		alg = jose.ES256
+	case []byte:
+		alg = jose.HS256
+	default:
+		return "", fmt.Errorf("stepca: unsupported JWK key type %T", key)
+	}
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: alg, Key: key}, nil)
+	if err != nil {
+		return "", fmt.Errorf("stepca: failed to create JWT signer: %w", err)
+	}
+
+	now := time.Now()
+	claims := jwt.Claims{
+		Issuer:    j.Name,
+		Subject:   j.Name,
+		Audience:  jwt.Audience{j.Audience},
+		Expiry:    jwt.NewNumericDate(now.Add(5 * time.Minute)),
+		NotBefore: jwt.NewNumericDate(now),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ID:        uuid.NewString(),
+	}
+	sha := map[string]interface{}{
+		"sha": hex.EncodeToString(csrSHA256[:]),
+	}
+
+	token, err := jwt.Signed(signer).Claims(claims).Claims(sha).CompactSerialize()
+	if err != nil {
+		return "", fmt.Errorf("stepca: failed to sign JWT: %w", err)
+	}
+	return token, nil
+}
+
+// loadKey reads KeyFile, decrypting it with Password first if it is a
+// JWE rather than a plain JWK.
+func (j JWK) loadKey() (interface{}, error) {
+	b, err := os.ReadFile(j.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("stepca: failed to read JWK key file: %w", err)
+	}
+
+	if j.Password == "" {
+		var key jose.JSONWebKey
+		if err := key.UnmarshalJSON(b); err != nil {
+			return nil, fmt.Errorf("stepca: failed to parse JWK: %w", err)
+		}
+		return key.Key, nil
+	}
+
+	enc, err := jose.ParseEncrypted(string(b))
+	if err != nil {
+		return nil, fmt.Errorf("stepca: failed to parse encrypted JWK: %w", err)
+	}
+
+	decrypted, err := enc.Decrypt([]byte(j.Password))
+	if err != nil {
+		return nil, fmt.Errorf("stepca: failed to decrypt JWK: %w", err)
+	}
+
+	var key jose.JSONWebKey
+	if err := key.UnmarshalJSON(decrypted); err != nil {
+		return nil, fmt.Errorf("stepca: failed to parse decrypted JWK: %w", err)
+	}
+	return key.Key, nil
+}
@@ -0,0 +1,178 @@
+package provisioner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// OIDC implements Provisioner by exchanging credentials for an OIDC ID
+// token, which step-ca's OIDC provisioner verifies directly against the
+// identity provider; unlike JWK it does not embed csrSHA256 itself,
+// since the ID token is signed by the provider, not us.
+type OIDC struct {
+	// ClientID is the OIDC client ID registered with the provider.
+	ClientID string
+	// ClientSecret is the OIDC client secret, if required by the
+	// provider's token endpoint.
+	ClientSecret string
+	// TokenURL is the provider's token endpoint.
+	TokenURL string
+	// RefreshToken, if set, is exchanged for a fresh ID token via the
+	// refresh_token grant on every call to Token.
+	RefreshToken string
+	// DeviceAuthURL, if set and RefreshToken is empty, drives the OAuth2
+	// device authorization flow instead, logging the verification URL
+	// and user code for the operator to complete out of band.
+	DeviceAuthURL string
+	// Scope is the OIDC scope requested during the device flow.
+	Scope string
+}
+
+// Token returns an OIDC ID token, via the refresh or device flow
+// depending on configuration.
+func (o OIDC) Token(ctx context.Context, _ [32]byte) (string, error) {
+	if o.RefreshToken != "" {
+		return o.refresh(ctx)
+	}
+	return o.deviceFlow(ctx)
+}
+
+func (o OIDC) refresh(ctx context.Context) (string, error) {
+	form := url.Values{
+		"grant_type":    {"refresh_token"},
+		"client_id":     {o.ClientID},
+		"client_secret": {o.ClientSecret},
+		"refresh_token": {o.RefreshToken},
+	}
+
+	return o.requestIDToken(ctx, o.TokenURL, form)
+}
+
+func (o OIDC) deviceFlow(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.DeviceAuthURL, strings.NewReader(url.Values{
+		"client_id": {o.ClientID},
+		"scope":     {o.Scope},
+	}.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("stepca: failed to build device authorization request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("stepca: device authorization request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var device struct {
+		DeviceCode      string `json:"device_code"`
+		UserCode        string `json:"user_code"`
+		VerificationURI string `json:"verification_uri"`
+		Interval        int    `json:"interval"`
+		ExpiresIn       int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&device); err != nil {
+		return "", fmt.Errorf("stepca: failed to decode device authorization response: %w", err)
+	}
+
+	log.Printf("certify: stepca: visit %s and enter code %s to authenticate", device.VerificationURI, device.UserCode)
+
+	interval := time.Duration(device.Interval) * time.Second
+	if interval == 0 {
+		interval = 5 * time.Second
+	}
+	deadline := time.Now().Add(time.Duration(device.ExpiresIn) * time.Second)
+
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(interval):
+		}
+
+		token, pending, err := o.pollDeviceToken(ctx, device.DeviceCode)
+		if err != nil {
+			return "", err
+		}
+		if !pending {
+			return token, nil
+		}
+	}
+
+	return "", fmt.Errorf("stepca: device flow timed out waiting for authorization")
+}
+
+func (o OIDC) pollDeviceToken(ctx context.Context, deviceCode string) (token string, pending bool, err error) {
+	form := url.Values{
+		"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+		"device_code": {deviceCode},
+		"client_id":   {o.ClientID},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", false, fmt.Errorf("stepca: failed to build device token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", false, fmt.Errorf("stepca: device token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IDToken string `json:"id_token"`
+		Error   string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", false, fmt.Errorf("stepca: failed to decode device token response: %w", err)
+	}
+
+	switch {
+	case body.IDToken != "":
+		return body.IDToken, false, nil
+	case body.Error == "authorization_pending", body.Error == "slow_down":
+		return "", true, nil
+	default:
+		return "", false, fmt.Errorf("stepca: device flow failed: %s", body.Error)
+	}
+}
+
+// requestIDToken POSTs form to tokenURL and returns the id_token field
+// of the response.
+func (o OIDC) requestIDToken(ctx context.Context, tokenURL string, form url.Values) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("stepca: failed to build OIDC token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("stepca: OIDC token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("stepca: OIDC token request returned %s", resp.Status)
+	}
+
+	var body struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("stepca: failed to decode OIDC token response: %w", err)
+	}
+	if body.IDToken == "" {
+		return "", fmt.Errorf("stepca: OIDC token response did not contain an id_token")
+	}
+
+	return body.IDToken, nil
+}
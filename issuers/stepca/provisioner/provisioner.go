@@ -0,0 +1,16 @@
+// Package provisioner implements the step-ca provisioner kinds used to
+// authorize a certificate request: JWK, OIDC and the cloud
+// instance-identity-document provisioners.
+package provisioner
+
+import "context"
+
+// Provisioner produces the one-time token (ott) step-ca's /1.0/sign
+// endpoint requires to authorize a certificate request.
+type Provisioner interface {
+	// Token returns the ott to send with a signing request for the CSR
+	// whose SHA-256 digest is csrSHA256. Provisioners that cannot embed
+	// csrSHA256 themselves (OIDC, the cloud IID provisioners) ignore it;
+	// step-ca validates the request against the provisioner out of band.
+	Token(ctx context.Context, csrSHA256 [32]byte) (string, error)
+}
@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// AppRole implements vault.AuthMethod using the AppRole auth backend.
+// https://www.vaultproject.io/docs/auth/approle
+type AppRole struct {
+	// MountPath is the path the AppRole auth method is mounted at.
+	MountPath string
+	// RoleID is the role_id of the AppRole to authenticate as.
+	RoleID string
+	// SecretID is the secret_id of the AppRole to authenticate as. If
+	// empty, SecretIDFile is used instead.
+	SecretID string
+	// SecretIDFile is a path to a file containing the secret_id. Used
+	// when SecretID is empty.
+	SecretIDFile string
+	// SecretIDEnv is the name of an environment variable containing the
+	// secret_id. Used when both SecretID and SecretIDFile are empty.
+	SecretIDEnv string
+
+	renewer
+}
+
+// SetToken implements vault.AuthMethod by logging in against the
+// AppRole auth backend. See renewer.start for renewal, cancellation and
+// re-authentication behavior.
+func (a *AppRole) SetToken(ctx context.Context, cli *api.Client) error {
+	return a.start(ctx, cli, a.login)
+}
+
+func (a *AppRole) login(ctx context.Context, cli *api.Client) (*api.Secret, error) {
+	secretID, err := a.secretID()
+	if err != nil {
+		return nil, err
+	}
+
+	mount := a.MountPath
+	if mount == "" {
+		mount = "approle"
+	}
+
+	return cli.Logical().WriteWithContext(ctx, fmt.Sprintf("auth/%s/login", mount), map[string]interface{}{
+		"role_id":   a.RoleID,
+		"secret_id": secretID,
+	})
+}
+
+func (a *AppRole) secretID() (string, error) {
+	if a.SecretID != "" {
+		return a.SecretID, nil
+	}
+	if a.SecretIDFile != "" {
+		b, err := os.ReadFile(a.SecretIDFile)
+		if err != nil {
+			return "", fmt.Errorf("auth: failed to read secret_id file: %w", err)
+		}
+		return strings.TrimSpace(string(b)), nil
+	}
+	if a.SecretIDEnv != "" {
+		return os.Getenv(a.SecretIDEnv), nil
+	}
+	return "", fmt.Errorf("auth: no secret_id, SecretIDFile or SecretIDEnv configured")
+}
@@ -0,0 +1,125 @@
+// Package auth provides AuthMethod implementations for the Vault issuer,
+// covering the standard non-deprecated Vault auth backends.
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+
+	"github.com/johanbrandhorst/certify/internal/retry"
+)
+
+// backoff bounds the jittered delay between re-authentication attempts
+// once a token's renewal has failed permanently.
+var backoff = retry.Waiter{Min: time.Second, Max: 6 * time.Second}
+
+// loginFunc performs a single login against Vault and returns the
+// resulting secret, which must carry a ClientToken.
+type loginFunc func(context.Context, *api.Client) (*api.Secret, error)
+
+// renewer provides the Close/stopWatcher bookkeeping shared by every
+// AuthMethod implementation in this package, so embedding it is enough
+// to make a type safe to re-authenticate or tear down repeatedly.
+type renewer struct {
+	mu          sync.Mutex
+	stopWatcher func()
+}
+
+// Close stops any in-flight renewal goroutine. It is safe to call more
+// than once, and from multiple AuthMethod implementations, since each
+// embeds its own renewer.
+func (r *renewer) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stopWatcher != nil {
+		r.stopWatcher()
+		r.stopWatcher = nil
+	}
+	return nil
+}
+
+// start cancels any watcher left over from a previous call, logs in via
+// login, and sets the resulting token on cli. If the token is
+// renewable, it spawns a new watcher goroutine tied to a fresh context
+// derived from root, so a later call to start or Close can stop it
+// without leaking the old one. root must be the long-lived context the
+// AuthMethod was originally given (e.g. by SetToken) - never a previous
+// generation's watch context, which start is about to cancel.
+func (r *renewer) start(root context.Context, cli *api.Client, login loginFunc) error {
+	r.mu.Lock()
+	if r.stopWatcher != nil {
+		r.stopWatcher()
+	}
+	watchCtx, cancel := context.WithCancel(root)
+	r.stopWatcher = cancel
+	r.mu.Unlock()
+
+	secret, err := login(root, cli)
+	if err != nil {
+		return fmt.Errorf("auth: login failed: %w", err)
+	}
+	if secret == nil || secret.Auth == nil || secret.Auth.ClientToken == "" {
+		return fmt.Errorf("auth: login response did not contain a client token")
+	}
+
+	cli.SetToken(secret.Auth.ClientToken)
+
+	if secret.Auth.Renewable {
+		go r.watch(root, watchCtx, cli, secret, login)
+	}
+
+	return nil
+}
+
+// watch starts a LifetimeWatcher for secret and blocks until watchCtx is
+// cancelled or the watcher exits. When renewal is no longer possible, it
+// re-authenticates from scratch via start, backing off with jitter so a
+// broken Vault server isn't hammered with login requests. root is
+// always passed through to the next start call, so each new generation
+// is tied to the original caller's context rather than to watchCtx,
+// which is cancelled as part of starting that new generation.
+func (r *renewer) watch(root, watchCtx context.Context, cli *api.Client, secret *api.Secret, login loginFunc) {
+	watcher, err := cli.NewLifetimeWatcher(&api.LifetimeWatcherInput{
+		Secret: secret,
+	})
+	if err != nil {
+		log.Printf("certify: vault auth: failed to create lifetime watcher: %v", err)
+		r.reauthenticate(root, watchCtx, cli, login)
+		return
+	}
+
+	go watcher.Start()
+	defer watcher.Stop()
+
+	for {
+		select {
+		case <-watchCtx.Done():
+			return
+		case err := <-watcher.DoneCh():
+			if err != nil {
+				log.Printf("certify: vault auth: token renewal stopped: %v", err)
+			}
+			r.reauthenticate(root, watchCtx, cli, login)
+			return
+		case <-watcher.RenewCh():
+		}
+	}
+}
+
+// reauthenticate waits out a jittered backoff - aborting early if
+// watchCtx is cancelled - and then calls start again with root, unless
+// watchCtx was cancelled first.
+func (r *renewer) reauthenticate(root, watchCtx context.Context, cli *api.Client, login loginFunc) {
+	if !backoff.Wait(watchCtx) {
+		return
+	}
+	if err := r.start(root, cli, login); err != nil {
+		log.Printf("certify: vault auth: re-authentication failed: %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// TestRenewerSurvivesRepeatedRenewalFailure drives renewer.start through
+// several consecutive renewal failures and asserts that it keeps
+// re-authenticating from scratch rather than going stale after the
+// first one, which is what a renewer.watch/reauthenticate call that
+// threads the wrong context would do.
+func TestRenewerSurvivesRepeatedRenewalFailure(t *testing.T) {
+	var logins int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/auth/test/login" {
+			atomic.AddInt32(&logins, 1)
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"auth": map[string]interface{}{
+					"client_token":   "test-token",
+					"renewable":      true,
+					"lease_duration": 1,
+				},
+			})
+			return
+		}
+		// Every renewal attempt fails, forcing the watcher to give up
+		// and reauthenticate from scratch.
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	cli, err := api.NewClient(&api.Config{Address: srv.URL})
+	if err != nil {
+		t.Fatalf("failed to build vault client: %v", err)
+	}
+
+	origBackoff := backoff
+	backoff.Min = time.Millisecond
+	backoff.Max = 5 * time.Millisecond
+	defer func() { backoff = origBackoff }()
+
+	login := func(ctx context.Context, cli *api.Client) (*api.Secret, error) {
+		return cli.Logical().WriteWithContext(ctx, "auth/test/login", nil)
+	}
+
+	var r renewer
+	defer r.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if err := r.start(ctx, cli, login); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+
+	// Each generation needs roughly a lease_duration (1s) before its
+	// LifetimeWatcher notices the forced renewal failure, so the 3rd
+	// login, which follows 2 failed generations, comes roughly 2s in.
+	deadline := time.Now().Add(2900 * time.Millisecond)
+	for time.Now().Before(deadline) && atomic.LoadInt32(&logins) < 3 {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if got := atomic.LoadInt32(&logins); got < 3 {
+		t.Fatalf("expected at least 3 logins after repeated renewal failures, got %d", got)
+	}
+}
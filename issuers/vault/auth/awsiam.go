@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/hashicorp/vault/api"
+)
+
+// AWSIAM implements vault.AuthMethod using the AWS auth backend's IAM
+// authentication type. It signs an sts:GetCallerIdentity request with
+// the credentials found via the default AWS credential chain and
+// submits it to Vault for verification.
+// https://www.vaultproject.io/docs/auth/aws#iam-auth-method
+type AWSIAM struct {
+	// MountPath is the path the AWS auth method is mounted at.
+	MountPath string
+	// Role is the Vault role to authenticate as. If empty, Vault infers
+	// it from the signed request's IAM principal.
+	Role string
+	// ServerID, if set, is sent as the X-Vault-AWS-IAM-Server-ID header,
+	// and must match the backend's configured iam_server_id_header_value.
+	ServerID string
+
+	renewer
+}
+
+// SetToken implements vault.AuthMethod by logging in against the AWS
+// IAM auth backend. See renewer.start for renewal, cancellation and
+// re-authentication behavior.
+func (a *AWSIAM) SetToken(ctx context.Context, cli *api.Client) error {
+	return a.start(ctx, cli, a.login)
+}
+
+func (a *AWSIAM) login(ctx context.Context, cli *api.Client) (*api.Secret, error) {
+	loginData, err := a.signGetCallerIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.Role != "" {
+		loginData["role"] = a.Role
+	}
+
+	mount := a.MountPath
+	if mount == "" {
+		mount = "aws"
+	}
+
+	return cli.Logical().WriteWithContext(ctx, fmt.Sprintf("auth/%s/login", mount), loginData)
+}
+
+// signGetCallerIdentity builds and signs an sts:GetCallerIdentity
+// request and returns it in the method/url/headers/body form the AWS
+// auth backend expects.
+func (a *AWSIAM) signGetCallerIdentity(ctx context.Context) (map[string]interface{}, error) {
+	sess, err := session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("auth: failed to create AWS session: %w", err)
+	}
+
+	svc := sts.New(sess)
+	req, _ := svc.GetCallerIdentityRequest(&sts.GetCallerIdentityInput{})
+	req.SetContext(ctx)
+
+	if a.ServerID != "" {
+		req.HTTPRequest.Header.Add("X-Vault-AWS-IAM-Server-ID", a.ServerID)
+	}
+
+	if err := req.Sign(); err != nil {
+		return nil, fmt.Errorf("auth: failed to sign GetCallerIdentity request: %w", err)
+	}
+
+	headers, err := json.Marshal(req.HTTPRequest.Header)
+	if err != nil {
+		return nil, fmt.Errorf("auth: failed to marshal signed headers: %w", err)
+	}
+
+	body, err := io.ReadAll(req.HTTPRequest.Body)
+	if err != nil {
+		return nil, fmt.Errorf("auth: failed to read signed request body: %w", err)
+	}
+
+	return map[string]interface{}{
+		"iam_http_request_method": req.HTTPRequest.Method,
+		"iam_request_url":         base64.StdEncoding.EncodeToString([]byte(req.HTTPRequest.URL.String())),
+		"iam_request_headers":     base64.StdEncoding.EncodeToString(headers),
+		"iam_request_body":        base64.StdEncoding.EncodeToString(body),
+	}, nil
+}
@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/hashicorp/vault/api"
+)
+
+// FileToken implements vault.AuthMethod by reading the Vault token from
+// a file, rather than embedding it in configuration. It watches the
+// file for changes and updates the client's token in place whenever the
+// contents change, without restarting the process. This integrates
+// cleanly with the Nomad vault {} stanza and Vault Agent's sink.file
+// output, both of which write short-lived tokens to disk and rotate
+// them.
+type FileToken struct {
+	// Path is the file to read the token from.
+	Path string
+	// EnvVar, if set, names an environment variable to read the token
+	// from instead of Path.
+	EnvVar string
+	// PollInterval, if set, polls Path on this interval instead of
+	// watching it with fsnotify.
+	PollInterval time.Duration
+
+	renewer
+}
+
+// SetToken reads the current token from Path (or EnvVar) and sets it on
+// cli, then watches Path for changes in the background until ctx is
+// cancelled. A second call to SetToken cancels the previous watcher
+// before starting a new one.
+func (f *FileToken) SetToken(ctx context.Context, cli *api.Client) error {
+	f.mu.Lock()
+	if f.stopWatcher != nil {
+		f.stopWatcher()
+	}
+	watchCtx, cancel := context.WithCancel(ctx)
+	f.stopWatcher = cancel
+	f.mu.Unlock()
+
+	token, err := f.read()
+	if err != nil {
+		return err
+	}
+	cli.SetToken(token)
+
+	if f.Path != "" {
+		go f.watch(watchCtx, cli)
+	}
+
+	return nil
+}
+
+// watch reloads the token whenever Path changes, either via fsnotify or
+// by polling, depending on whether PollInterval is set.
+func (f *FileToken) watch(ctx context.Context, cli *api.Client) {
+	if f.PollInterval > 0 {
+		f.poll(ctx, cli)
+		return
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Printf("certify: vault auth: failed to create file watcher: %v", err)
+		return
+	}
+	defer watcher.Close()
+
+	// Watch the containing directory rather than the file itself, since
+	// Vault Agent and the Nomad vault {} stanza rotate the token by
+	// writing a new file and renaming it into place, which most
+	// filesystems deliver as events on the directory, not the old inode.
+	dir := filepath.Dir(f.Path)
+	if err := watcher.Add(dir); err != nil {
+		log.Printf("certify: vault auth: failed to watch %s: %v", dir, err)
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != filepath.Clean(f.Path) {
+				continue
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+			f.reload(cli)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("certify: vault auth: file watcher error: %v", err)
+		}
+	}
+}
+
+func (f *FileToken) poll(ctx context.Context, cli *api.Client) {
+	ticker := time.NewTicker(f.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			f.reload(cli)
+		}
+	}
+}
+
+func (f *FileToken) reload(cli *api.Client) {
+	token, err := f.read()
+	if err != nil {
+		log.Printf("certify: vault auth: failed to reload token: %v", err)
+		return
+	}
+	cli.SetToken(token)
+}
+
+func (f *FileToken) read() (string, error) {
+	if f.EnvVar != "" {
+		if v := os.Getenv(f.EnvVar); v != "" {
+			return v, nil
+		}
+	}
+	if f.Path == "" {
+		return "", fmt.Errorf("auth: no Path or EnvVar configured")
+	}
+	b, err := os.ReadFile(f.Path)
+	if err != nil {
+		return "", fmt.Errorf("auth: failed to read token file: %w", err)
+	}
+	return strings.TrimSpace(string(b)), nil
+}
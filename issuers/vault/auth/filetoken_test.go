@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+)
+
+func waitForToken(t *testing.T, cli *api.Client, want string) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if cli.Token() == want {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("token not updated, want %q, got %q", want, cli.Token())
+}
+
+// TestFileTokenWatch covers the fsnotify path: SetToken picks up the
+// initial token, and a rename-based rewrite of the file (as Vault
+// Agent's sink.file and the Nomad vault {} stanza perform token
+// rotation) is picked up by the background watcher.
+func TestFileTokenWatch(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "token")
+
+	if err := os.WriteFile(path, []byte("initial-token\n"), 0o600); err != nil {
+		t.Fatalf("failed to write token file: %v", err)
+	}
+
+	cli, err := api.NewClient(api.DefaultConfig())
+	if err != nil {
+		t.Fatalf("failed to build vault client: %v", err)
+	}
+
+	f := &FileToken{Path: path}
+	defer f.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := f.SetToken(ctx, cli); err != nil {
+		t.Fatalf("SetToken failed: %v", err)
+	}
+	if got, want := cli.Token(), "initial-token"; got != want {
+		t.Fatalf("got token %q, want %q", got, want)
+	}
+
+	// Give the background watcher goroutine time to register its
+	// fsnotify watch on dir before rotating the file, since SetToken
+	// returns as soon as the goroutine is launched, not once it's
+	// actually watching.
+	time.Sleep(100 * time.Millisecond)
+
+	// Rewrite the file via a rename, the way Vault Agent and Nomad
+	// rotate tokens in place.
+	rotated := filepath.Join(dir, "token.new")
+	if err := os.WriteFile(rotated, []byte("rotated-token\n"), 0o600); err != nil {
+		t.Fatalf("failed to write rotated token file: %v", err)
+	}
+	if err := os.Rename(rotated, path); err != nil {
+		t.Fatalf("failed to rotate token file: %v", err)
+	}
+
+	waitForToken(t, cli, "rotated-token")
+}
+
+// TestFileTokenPoll covers the PollInterval path.
+func TestFileTokenPoll(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "token")
+
+	if err := os.WriteFile(path, []byte("initial-token\n"), 0o600); err != nil {
+		t.Fatalf("failed to write token file: %v", err)
+	}
+
+	cli, err := api.NewClient(api.DefaultConfig())
+	if err != nil {
+		t.Fatalf("failed to build vault client: %v", err)
+	}
+
+	f := &FileToken{Path: path, PollInterval: 20 * time.Millisecond}
+	defer f.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := f.SetToken(ctx, cli); err != nil {
+		t.Fatalf("SetToken failed: %v", err)
+	}
+	if got, want := cli.Token(), "initial-token"; got != want {
+		t.Fatalf("got token %q, want %q", got, want)
+	}
+
+	if err := os.WriteFile(path, []byte("polled-token\n"), 0o600); err != nil {
+		t.Fatalf("failed to rewrite token file: %v", err)
+	}
+
+	waitForToken(t, cli, "polled-token")
+}
@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// JWT implements vault.AuthMethod using the JWT/OIDC auth backend's
+// JWT login flow, i.e. it presents an already-issued JWT (for example
+// an OIDC ID token obtained out of band) rather than driving the
+// interactive OIDC device flow itself.
+// https://www.vaultproject.io/docs/auth/jwt#jwt-login
+type JWT struct {
+	// MountPath is the path the JWT/OIDC auth method is mounted at.
+	MountPath string
+	// Role is the Vault role to authenticate as.
+	Role string
+	// JWT is the token to present to Vault. If empty, JWTPath is used,
+	// falling back to JWTEnv.
+	JWT string
+	// JWTPath is a path to a file containing the JWT.
+	JWTPath string
+	// JWTEnv is the name of an environment variable containing the JWT.
+	JWTEnv string
+
+	renewer
+}
+
+// SetToken implements vault.AuthMethod by logging in against the
+// JWT/OIDC auth backend. See renewer.start for renewal, cancellation
+// and re-authentication behavior.
+func (j *JWT) SetToken(ctx context.Context, cli *api.Client) error {
+	return j.start(ctx, cli, j.login)
+}
+
+func (j *JWT) login(ctx context.Context, cli *api.Client) (*api.Secret, error) {
+	token, err := j.token()
+	if err != nil {
+		return nil, err
+	}
+
+	mount := j.MountPath
+	if mount == "" {
+		mount = "jwt"
+	}
+
+	return cli.Logical().WriteWithContext(ctx, fmt.Sprintf("auth/%s/login", mount), map[string]interface{}{
+		"role": j.Role,
+		"jwt":  token,
+	})
+}
+
+func (j *JWT) token() (string, error) {
+	if j.JWT != "" {
+		return j.JWT, nil
+	}
+	if j.JWTPath != "" {
+		b, err := os.ReadFile(j.JWTPath)
+		if err != nil {
+			return "", fmt.Errorf("auth: failed to read JWT file: %w", err)
+		}
+		return strings.TrimSpace(string(b)), nil
+	}
+	if j.JWTEnv != "" {
+		return os.Getenv(j.JWTEnv), nil
+	}
+	return "", fmt.Errorf("auth: no JWT, JWTPath or JWTEnv configured")
+}
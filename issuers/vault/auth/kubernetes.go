@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// defaultServiceAccountTokenPath is the path the Kubernetes API server
+// projects the pod's service account JWT to.
+const defaultServiceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// Kubernetes implements vault.AuthMethod using the Kubernetes auth
+// backend. https://www.vaultproject.io/docs/auth/kubernetes
+type Kubernetes struct {
+	// MountPath is the path the Kubernetes auth method is mounted at.
+	MountPath string
+	// Role is the Vault role to authenticate as.
+	Role string
+	// JWTPath is the path of the service account JWT to present to
+	// Vault. Defaults to the projected service account token path.
+	JWTPath string
+
+	renewer
+}
+
+// SetToken implements vault.AuthMethod by logging in against the
+// Kubernetes auth backend. See renewer.start for renewal, cancellation
+// and re-authentication behavior.
+func (k *Kubernetes) SetToken(ctx context.Context, cli *api.Client) error {
+	return k.start(ctx, cli, k.login)
+}
+
+func (k *Kubernetes) login(ctx context.Context, cli *api.Client) (*api.Secret, error) {
+	path := k.JWTPath
+	if path == "" {
+		path = defaultServiceAccountTokenPath
+	}
+
+	jwt, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("auth: failed to read service account token: %w", err)
+	}
+
+	mount := k.MountPath
+	if mount == "" {
+		mount = "kubernetes"
+	}
+
+	return cli.Logical().WriteWithContext(ctx, fmt.Sprintf("auth/%s/login", mount), map[string]interface{}{
+		"role": k.Role,
+		"jwt":  strings.TrimSpace(string(jwt)),
+	})
+}
@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// TLSCert implements vault.AuthMethod using the TLS Certificates auth
+// backend. It relies on the Vault API client already being configured
+// with a client certificate, e.g. via api.Config.ConfigureTLS.
+// https://www.vaultproject.io/docs/auth/cert
+type TLSCert struct {
+	// MountPath is the path the cert auth method is mounted at.
+	MountPath string
+	// Name is the name of the certificate role to authenticate against.
+	// If empty, Vault matches the presented certificate against all
+	// configured roles.
+	Name string
+
+	renewer
+}
+
+// SetToken implements vault.AuthMethod by logging in against the cert
+// auth backend. See renewer.start for renewal, cancellation and
+// re-authentication behavior.
+func (t *TLSCert) SetToken(ctx context.Context, cli *api.Client) error {
+	return t.start(ctx, cli, t.login)
+}
+
+func (t *TLSCert) login(ctx context.Context, cli *api.Client) (*api.Secret, error) {
+	mount := t.MountPath
+	if mount == "" {
+		mount = "cert"
+	}
+
+	data := map[string]interface{}{}
+	if t.Name != "" {
+		data["name"] = t.Name
+	}
+
+	return cli.Logical().WriteWithContext(ctx, fmt.Sprintf("auth/%s/login", mount), data)
+}
@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// Userpass implements vault.AuthMethod using the Userpass auth backend.
+// https://www.vaultproject.io/docs/auth/userpass
+type Userpass struct {
+	// MountPath is the path the Userpass auth method is mounted at.
+	MountPath string
+	// Username is the username to authenticate as.
+	Username string
+	// Password is the password to authenticate with. If empty,
+	// PasswordFile is used instead.
+	Password string
+	// PasswordFile is a path to a file containing the password. Used
+	// when Password is empty.
+	PasswordFile string
+	// PasswordEnv is the name of an environment variable containing the
+	// password. Used when both Password and PasswordFile are empty.
+	PasswordEnv string
+
+	renewer
+}
+
+// SetToken implements vault.AuthMethod by logging in against the
+// Userpass auth backend. See renewer.start for renewal, cancellation
+// and re-authentication behavior.
+func (u *Userpass) SetToken(ctx context.Context, cli *api.Client) error {
+	return u.start(ctx, cli, u.login)
+}
+
+func (u *Userpass) login(ctx context.Context, cli *api.Client) (*api.Secret, error) {
+	password, err := u.password()
+	if err != nil {
+		return nil, err
+	}
+
+	mount := u.MountPath
+	if mount == "" {
+		mount = "userpass"
+	}
+
+	return cli.Logical().WriteWithContext(ctx, fmt.Sprintf("auth/%s/login/%s", mount, u.Username), map[string]interface{}{
+		"password": password,
+	})
+}
+
+func (u *Userpass) password() (string, error) {
+	if u.Password != "" {
+		return u.Password, nil
+	}
+	if u.PasswordFile != "" {
+		b, err := os.ReadFile(u.PasswordFile)
+		if err != nil {
+			return "", fmt.Errorf("auth: failed to read password file: %w", err)
+		}
+		return strings.TrimSpace(string(b)), nil
+	}
+	if u.PasswordEnv != "" {
+		return os.Getenv(u.PasswordEnv), nil
+	}
+	return "", fmt.Errorf("auth: no password, PasswordFile or PasswordEnv configured")
+}
@@ -0,0 +1,18 @@
+package vault
+
+// Issuer requests certificates from the Vault PKI secrets engine,
+// authenticating against Vault via the configured AuthMethod.
+type Issuer struct {
+	// AuthMethod is used to authenticate against Vault.
+	AuthMethod AuthMethod
+}
+
+// Close stops any background renewal goroutine started by the
+// configured AuthMethod, making it safe to discard or replace the
+// Issuer at runtime, e.g. when hot-reloading configuration.
+func (i *Issuer) Close() error {
+	if i.AuthMethod == nil {
+		return nil
+	}
+	return i.AuthMethod.Close()
+}
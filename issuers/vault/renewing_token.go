@@ -0,0 +1,121 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+
+	"github.com/johanbrandhorst/certify/internal/retry"
+)
+
+// renewingTokenBackoff bounds the jittered delay before a renewing
+// token is re-authenticated from scratch after its watcher stops.
+var renewingTokenBackoff = retry.Waiter{Min: time.Second, Max: 6 * time.Second}
+
+// RenewingToken implements AuthMethod by authenticating with a single
+// initial renewable token and keeping it renewed in the background for
+// as long as the Issuer is in use.
+type RenewingToken struct {
+	// Initial is the token used to initially authenticate against
+	// Vault. It must be renewable.
+	Initial string
+	// RenewBefore is how long before the expiry of the token it should
+	// be renewed. It is not passed to the LifetimeWatcher, which picks
+	// its own renewal times, so it has no effect.
+	RenewBefore time.Duration
+	// TimeToLive is how long the renewed token should be valid for.
+	TimeToLive time.Duration
+
+	mu          sync.Mutex
+	stopWatcher func()
+}
+
+// SetToken sets cli's token to Initial and starts a background
+// LifetimeWatcher that keeps it renewed. A second call to SetToken
+// cancels the previous watcher before starting a new one. ctx must be
+// the long-lived context this RenewingToken is meant to run under -
+// reauthenticate always re-derives the next watch context from it,
+// rather than from a previous (about-to-be-cancelled) watch context.
+func (r *RenewingToken) SetToken(ctx context.Context, cli *api.Client) error {
+	r.mu.Lock()
+	if r.stopWatcher != nil {
+		r.stopWatcher()
+	}
+	watchCtx, cancel := context.WithCancel(ctx)
+	r.stopWatcher = cancel
+	r.mu.Unlock()
+
+	cli.SetToken(r.Initial)
+
+	secret, err := cli.Auth().Token().RenewSelf(int(r.TimeToLive.Seconds()))
+	if err != nil {
+		return fmt.Errorf("vault: failed to renew initial token: %w", err)
+	}
+
+	go r.watch(ctx, watchCtx, cli, secret)
+
+	return nil
+}
+
+// Close stops the background renewal goroutine, if any.
+func (r *RenewingToken) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stopWatcher != nil {
+		r.stopWatcher()
+		r.stopWatcher = nil
+	}
+	return nil
+}
+
+// watch keeps secret's token renewed via a LifetimeWatcher until
+// watchCtx is cancelled. If renewal stops for any other reason, it
+// re-authenticates from scratch after a jittered backoff. ctx is always
+// passed through to the next SetToken call, so each new generation is
+// tied to the original caller's context rather than to watchCtx, which
+// is cancelled as part of starting that new generation.
+func (r *RenewingToken) watch(ctx, watchCtx context.Context, cli *api.Client, secret *api.Secret) {
+	watcher, err := cli.NewLifetimeWatcher(&api.LifetimeWatcherInput{
+		Secret:    secret,
+		Increment: int(r.TimeToLive.Seconds()),
+	})
+	if err != nil {
+		log.Printf("certify: vault: failed to create lifetime watcher: %v", err)
+		r.reauthenticate(ctx, watchCtx, cli)
+		return
+	}
+
+	go watcher.Start()
+	defer watcher.Stop()
+
+	for {
+		select {
+		case <-watchCtx.Done():
+			return
+		case err := <-watcher.DoneCh():
+			if err != nil {
+				log.Printf("certify: vault: token renewal stopped: %v", err)
+			}
+			r.reauthenticate(ctx, watchCtx, cli)
+			return
+		case <-watcher.RenewCh():
+		}
+	}
+}
+
+// reauthenticate waits out a jittered backoff - aborting early if
+// watchCtx is cancelled - and then calls SetToken again with ctx,
+// unless watchCtx was cancelled first.
+func (r *RenewingToken) reauthenticate(ctx, watchCtx context.Context, cli *api.Client) {
+	if !renewingTokenBackoff.Wait(watchCtx) {
+		return
+	}
+	if err := r.SetToken(ctx, cli); err != nil {
+		log.Printf("certify: vault: re-authentication failed: %v", err)
+	}
+}
@@ -12,6 +12,10 @@ import (
 // custom authentication against the Vault server.
 type AuthMethod interface {
 	SetToken(context.Context, *api.Client) error
+	// Close stops any background renewal goroutine started by
+	// SetToken. It is safe to call even if SetToken was never called,
+	// and to call more than once.
+	Close() error
 }
 
 // ConstantToken implements AuthMethod with a constant token
@@ -23,6 +27,12 @@ func (c ConstantToken) SetToken(_ context.Context, cli *api.Client) (error) {
 	return nil
 }
 
+// Close is a no-op, since ConstantToken has no background goroutine to
+// stop.
+func (c ConstantToken) Close() error {
+	return nil
+}
+
 
 // https://www.vaultproject.io/api/secret/pki/index.html#parameters-14
 type csrOpts struct {